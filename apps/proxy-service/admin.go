@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Reload diffs entries against the pool's current proxies (matched by
+// host:port), preserving accumulated stats for proxies that are still
+// present, adding newly-listed ones, and dropping ones no longer listed.
+// It does not touch the health-check goroutine, which simply checks
+// whatever is in the pool on its next tick.
+// REQ-GO-017: Hot-reloadable proxy configuration
+func (p *ProxyPool) Reload(entries []proxyEntry) {
+	defaultCheck := activeHealthCheckConfigFromEnv()
+
+	p.mu.Lock()
+
+	existing := make(map[string]*ProxyInfo, len(p.proxies))
+	for _, proxy := range p.proxies {
+		existing[proxy.Host+":"+proxy.Port] = proxy
+	}
+
+	updated := make([]*ProxyInfo, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+
+	for _, entry := range entries {
+		info := parseProxyURL(entry.URL)
+		if info == nil {
+			continue
+		}
+
+		weight := entry.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		check := defaultCheck
+		if entry.HealthCheck != nil {
+			check = *entry.HealthCheck
+		}
+
+		key := info.Host + ":" + info.Port
+		seen[key] = true
+
+		if prior, ok := existing[key]; ok {
+			// Keep the existing ProxyInfo (and its accumulated stats),
+			// just refresh its configurable fields.
+			prior.Weight = weight
+			prior.ActiveCheck = check
+			updated = append(updated, prior)
+			continue
+		}
+
+		info.Weight = weight
+		info.ActiveCheck = check
+		log.Printf("[RELOAD] Adding proxy: %s (protocol: %s)", info.Host, info.Protocol)
+		updated = append(updated, info)
+	}
+
+	for key := range existing {
+		if !seen[key] {
+			log.Printf("[RELOAD] Removing proxy: %s", key)
+		}
+	}
+
+	p.proxies = updated
+
+	p.mu.Unlock()
+
+	log.Printf("[RELOAD] Pool now has %d proxies", len(updated))
+	p.updatePoolGauges()
+}
+
+// AddProxy appends a single proxy to the pool. It fails if a proxy with
+// rawURL's host:port is already present.
+func (p *ProxyPool) AddProxy(rawURL string, weight int) (*ProxyInfo, error) {
+	info := parseProxyURL(rawURL)
+	if info == nil {
+		return nil, fmt.Errorf("invalid proxy URL %q", rawURL)
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	info.Weight = weight
+	info.ActiveCheck = activeHealthCheckConfigFromEnv()
+
+	key := info.Host + ":" + info.Port
+
+	p.mu.Lock()
+	for _, existing := range p.proxies {
+		if existing.Host+":"+existing.Port == key {
+			p.mu.Unlock()
+			return nil, fmt.Errorf("proxy %s already in pool", key)
+		}
+	}
+	p.proxies = append(p.proxies, info)
+	p.mu.Unlock()
+
+	log.Printf("[ADMIN] Added proxy: %s (protocol: %s)", info.Host, info.Protocol)
+	p.updatePoolGauges()
+
+	return info, nil
+}
+
+// RemoveProxy drops the proxy identified by "host:port" from the pool.
+func (p *ProxyPool) RemoveProxy(hostPort string) error {
+	p.mu.Lock()
+	removed := false
+	for i, proxy := range p.proxies {
+		if proxy.Host+":"+proxy.Port == hostPort {
+			p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	p.mu.Unlock()
+
+	if !removed {
+		return fmt.Errorf("proxy %s not found in pool", hostPort)
+	}
+
+	log.Printf("[ADMIN] Removed proxy: %s", hostPort)
+	p.updatePoolGauges()
+
+	return nil
+}
+
+// adminTokenValid checks the request's bearer token against
+// PROXY_SERVICE_ADMIN_TOKEN. The admin API is disabled (every request
+// rejected) unless that env var is set.
+func adminTokenValid(r *http.Request) bool {
+	token := os.Getenv("PROXY_SERVICE_ADMIN_TOKEN")
+	if token == "" {
+		return false
+	}
+	want := "Bearer " + token
+	got := r.Header.Get("Authorization")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// handleAdminReload re-reads proxies.json and applies it via Reload.
+// REQ-GO-017: POST /admin/reload
+func (p *ProxyPool) handleAdminReload(w http.ResponseWriter, r *http.Request) {
+	if !adminTokenValid(r) {
+		sendErrorResponse(w, "Unauthorized", "missing or invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPost {
+		sendErrorResponse(w, "Method not allowed", "Only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entries := loadProxiesFromFile("proxies.json")
+	p.Reload(entries)
+
+	p.mu.RLock()
+	total := len(p.proxies)
+	p.mu.RUnlock()
+
+	sendJSONResponse(w, map[string]interface{}{
+		"status": "reloaded",
+		"total":  total,
+	}, http.StatusOK)
+}
+
+// addProxyRequest is the JSON body accepted by POST /admin/proxies.
+type addProxyRequest struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// handleAdminProxies adds a single proxy to the running pool.
+// REQ-GO-017: POST /admin/proxies
+func (p *ProxyPool) handleAdminProxies(w http.ResponseWriter, r *http.Request) {
+	if !adminTokenValid(r) {
+		sendErrorResponse(w, "Unauthorized", "missing or invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPost {
+		sendErrorResponse(w, "Method not allowed", "Only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req addProxyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.URL == "" {
+		sendErrorResponse(w, "Invalid request", "body must be JSON with a non-empty \"url\"", http.StatusBadRequest)
+		return
+	}
+
+	proxy, err := p.AddProxy(req.URL, req.Weight)
+	if err != nil {
+		sendErrorResponse(w, "Add failed", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sendJSONResponse(w, proxy, http.StatusCreated)
+}
+
+// handleAdminProxyByKey removes a single proxy, identified by "host:port"
+// in the URL path, from the running pool.
+// REQ-GO-017: DELETE /admin/proxies/{host:port}
+func (p *ProxyPool) handleAdminProxyByKey(w http.ResponseWriter, r *http.Request) {
+	if !adminTokenValid(r) {
+		sendErrorResponse(w, "Unauthorized", "missing or invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		sendErrorResponse(w, "Method not allowed", "Only DELETE is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/admin/proxies/")
+	if key == "" {
+		sendErrorResponse(w, "Invalid request", "missing host:port in path", http.StatusBadRequest)
+		return
+	}
+
+	if err := p.RemoveProxy(key); err != nil {
+		sendErrorResponse(w, "Remove failed", err.Error(), http.StatusNotFound)
+		return
+	}
+
+	sendJSONResponse(w, map[string]interface{}{
+		"status": "removed",
+		"proxy":  key,
+	}, http.StatusOK)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestReloadPreservesStatsForMatchedProxies(t *testing.T) {
+	existing := &ProxyInfo{URL: "http://a:1", Host: "a", Port: "1", Weight: 1, SuccessCount: 42, FailureCount: 2}
+	pool := &ProxyPool{proxies: []*ProxyInfo{existing}}
+
+	pool.Reload([]proxyEntry{{URL: "http://a:1", Weight: 5}})
+
+	if len(pool.proxies) != 1 {
+		t.Fatalf("pool has %d proxies, want 1", len(pool.proxies))
+	}
+	got := pool.proxies[0]
+	if got != existing {
+		t.Fatalf("Reload replaced the existing *ProxyInfo for a matched host:port instead of reusing it")
+	}
+	if got.SuccessCount != 42 || got.FailureCount != 2 {
+		t.Errorf("Reload lost accumulated stats: SuccessCount=%d, FailureCount=%d, want 42, 2", got.SuccessCount, got.FailureCount)
+	}
+	if got.Weight != 5 {
+		t.Errorf("Reload did not refresh Weight: got %d, want 5", got.Weight)
+	}
+}
+
+func TestReloadAddsNewlyListedProxies(t *testing.T) {
+	pool := &ProxyPool{proxies: []*ProxyInfo{}}
+
+	pool.Reload([]proxyEntry{{URL: "http://b:2"}})
+
+	if len(pool.proxies) != 1 {
+		t.Fatalf("pool has %d proxies, want 1", len(pool.proxies))
+	}
+	if pool.proxies[0].Host != "b" || pool.proxies[0].Port != "2" {
+		t.Errorf("Reload added %s:%s, want b:2", pool.proxies[0].Host, pool.proxies[0].Port)
+	}
+}
+
+func TestReloadDropsProxiesNoLongerListed(t *testing.T) {
+	pool := &ProxyPool{
+		proxies: []*ProxyInfo{
+			{URL: "http://a:1", Host: "a", Port: "1"},
+			{URL: "http://b:2", Host: "b", Port: "2"},
+		},
+	}
+
+	pool.Reload([]proxyEntry{{URL: "http://a:1"}})
+
+	if len(pool.proxies) != 1 {
+		t.Fatalf("pool has %d proxies, want 1", len(pool.proxies))
+	}
+	if pool.proxies[0].Host != "a" {
+		t.Errorf("Reload kept %s, want only a", pool.proxies[0].Host)
+	}
+}
+
+func TestAdminTokenValidRejectsMismatch(t *testing.T) {
+	t.Setenv("PROXY_SERVICE_ADMIN_TOKEN", "correct-token")
+
+	req := &http.Request{Header: http.Header{"Authorization": []string{"Bearer wrong-token"}}}
+	if adminTokenValid(req) {
+		t.Error("adminTokenValid accepted a mismatched bearer token")
+	}
+
+	req.Header.Set("Authorization", "Bearer correct-token")
+	if !adminTokenValid(req) {
+		t.Error("adminTokenValid rejected the correct bearer token")
+	}
+}
+
+func TestAdminTokenValidRejectsWhenUnset(t *testing.T) {
+	t.Setenv("PROXY_SERVICE_ADMIN_TOKEN", "")
+
+	req := &http.Request{Header: http.Header{"Authorization": []string{"Bearer anything"}}}
+	if adminTokenValid(req) {
+		t.Error("adminTokenValid accepted a request when PROXY_SERVICE_ADMIN_TOKEN is unset")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSplitAndTrim(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a, b , c", []string{"a", "b", "c"}},
+		{" , , ", nil},
+	}
+
+	for _, tc := range cases {
+		got := splitAndTrim(tc.in, ",")
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitAndTrim(%q, \",\") = %#v, want %#v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestStripHopByHopHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Connection", "Keep-Alive, X-Custom-Hop")
+	h.Set("Keep-Alive", "timeout=5")
+	h.Set("Proxy-Authorization", "Basic secret")
+	h.Set("TE", "trailers")
+	h.Set("Trailer", "X-Checksum")
+	h.Set("Transfer-Encoding", "chunked")
+	h.Set("Upgrade", "websocket")
+	h.Set("X-Custom-Hop", "should be dropped via Connection header")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Request-Id", "abc123")
+
+	stripHopByHopHeaders(h)
+
+	for _, name := range []string{
+		"Connection", "Keep-Alive", "Proxy-Authorization", "TE",
+		"Trailer", "Transfer-Encoding", "Upgrade", "X-Custom-Hop",
+	} {
+		if h.Get(name) != "" {
+			t.Errorf("header %q survived stripHopByHopHeaders, want removed", name)
+		}
+	}
+
+	for _, name := range []string{"Content-Type", "X-Request-Id"} {
+		if h.Get(name) == "" {
+			t.Errorf("header %q was removed, want kept", name)
+		}
+	}
+}
+
+func TestCopyHeaders(t *testing.T) {
+	src := http.Header{}
+	src.Add("X-Multi", "one")
+	src.Add("X-Multi", "two")
+
+	dst := http.Header{}
+	copyHeaders(dst, src)
+
+	if got := dst.Values("X-Multi"); !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Errorf("copyHeaders produced X-Multi = %#v, want [one two]", got)
+	}
+}
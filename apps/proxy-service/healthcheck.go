@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ActiveHealthCheckConfig describes an HTTP-level probe issued through a
+// proxy to prove it actually forwards traffic, not just that its TCP port is
+// open. A zero-value config (empty Path) falls back to a plain TCP dial.
+// REQ-GO-011: Active HTTP-level health checks
+type ActiveHealthCheckConfig struct {
+	Path           string        `json:"path"`            // e.g. "http://httpbin.org/ip"
+	ExpectedStatus string        `json:"expected_status"` // e.g. "200-299"; empty means any 2xx
+	ExpectedBody   string        `json:"expected_body"`   // substring that must appear in the response body
+	TimeoutSeconds int           `json:"timeout_seconds"` // per-check timeout
+	Timeout        time.Duration `json:"-"`
+}
+
+// activeHealthCheckConfigFromEnv builds the default active health-check
+// config applied to proxies.json entries that don't specify their own.
+func activeHealthCheckConfigFromEnv() ActiveHealthCheckConfig {
+	cfg := ActiveHealthCheckConfig{
+		Path:           os.Getenv("PROXY_SERVICE_HEALTHCHECK_PATH"),
+		ExpectedStatus: os.Getenv("PROXY_SERVICE_HEALTHCHECK_EXPECTED_STATUS"),
+		ExpectedBody:   os.Getenv("PROXY_SERVICE_HEALTHCHECK_EXPECTED_BODY"),
+		TimeoutSeconds: 5,
+	}
+
+	if val := os.Getenv("PROXY_SERVICE_HEALTHCHECK_TIMEOUT"); val != "" {
+		if n, err := fmt.Sscanf(val, "%d", &cfg.TimeoutSeconds); err == nil && n == 1 && cfg.TimeoutSeconds > 0 {
+			// value already written into cfg.TimeoutSeconds
+		}
+	}
+
+	cfg.Timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	return cfg
+}
+
+// statusRange parses an "expected_status" range like "200-299" or a
+// single code like "200". An empty range defaults to 200-299.
+func (c ActiveHealthCheckConfig) statusRange() (int, int) {
+	if c.ExpectedStatus == "" {
+		return 200, 299
+	}
+
+	parts := strings.SplitN(c.ExpectedStatus, "-", 2)
+	min := 0
+	max := 0
+	fmt.Sscanf(parts[0], "%d", &min)
+	if len(parts) == 2 {
+		fmt.Sscanf(parts[1], "%d", &max)
+	} else {
+		max = min
+	}
+	return min, max
+}
+
+// getCooldownDuration controls how long a proxy sits out after passive
+// failures trip its cooldown, before it's eligible for selection again.
+func getCooldownDuration() time.Duration {
+	seconds := 30 // Default cooldown
+
+	if val := os.Getenv("PROXY_SERVICE_HEALTHCHECK_COOLDOWN"); val != "" {
+		if n, err := fmt.Sscanf(val, "%d", &seconds); err == nil && n == 1 && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// inCooldown reports whether passive failures have put proxy in a
+// time-boxed cooldown, independent of its IsHealthy flag.
+func (proxy *ProxyInfo) inCooldown() bool {
+	return !proxy.CooldownUntil.IsZero() && time.Now().Before(proxy.CooldownUntil)
+}
+
+// checkProxyHealth validates that a proxy is reachable and, if an active
+// check is configured, that it actually proxies traffic correctly.
+// REQ-GO-004: Validates proxy availability
+func (p *ProxyPool) checkProxyHealth(proxy *ProxyInfo) bool {
+	if proxy.ActiveCheck.Path != "" {
+		return p.checkProxyHealthActive(proxy)
+	}
+	return p.checkProxyHealthTCP(proxy)
+}
+
+// checkProxyHealthTCP is the original dial-only probe, kept as the fallback
+// for proxies without an active check path configured.
+func (p *ProxyPool) checkProxyHealthTCP(proxy *ProxyInfo) bool {
+	timeout := 5 * time.Second
+	start := time.Now()
+
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%s", proxy.Host, proxy.Port), timeout)
+
+	elapsed := time.Since(start).Milliseconds()
+	proxy.ResponseTime = elapsed
+	proxy.LastChecked = time.Now()
+	healthcheckDuration.Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		p.recordHealthCheckFailure(proxy, err)
+		return false
+	}
+
+	conn.Close()
+	p.recordHealthCheckSuccess(proxy, elapsed)
+	return true
+}
+
+// checkProxyHealthActive issues a real HTTP GET through the proxy to
+// ActiveCheck.Path and validates the status code and body, proving the
+// upstream actually forwards traffic rather than just accepting TCP
+// connections.
+func (p *ProxyPool) checkProxyHealthActive(proxy *ProxyInfo) bool {
+	check := proxy.ActiveCheck
+	timeout := check.Timeout
+	if timeout <= 0 && check.TimeoutSeconds > 0 {
+		timeout = time.Duration(check.TimeoutSeconds) * time.Second
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		p.recordHealthCheckFailure(proxy, err)
+		return false
+	}
+
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+	}
+
+	start := time.Now()
+	resp, err := client.Get(check.Path)
+	elapsed := time.Since(start).Milliseconds()
+	proxy.ResponseTime = elapsed
+	proxy.LastChecked = time.Now()
+	healthcheckDuration.Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		p.recordHealthCheckFailure(proxy, err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	min, max := check.statusRange()
+	if resp.StatusCode < min || resp.StatusCode > max {
+		p.recordHealthCheckFailure(proxy, fmt.Errorf("unexpected status %d (want %d-%d)", resp.StatusCode, min, max))
+		return false
+	}
+
+	if check.ExpectedBody != "" {
+		body, readErr := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if readErr != nil || !strings.Contains(string(body), check.ExpectedBody) {
+			p.recordHealthCheckFailure(proxy, fmt.Errorf("response body did not contain %q", check.ExpectedBody))
+			return false
+		}
+	}
+
+	p.recordHealthCheckSuccess(proxy, elapsed)
+	return true
+}
+
+// recordHealthCheckFailure is shared by the TCP and active probes.
+func (p *ProxyPool) recordHealthCheckFailure(proxy *ProxyInfo, err error) {
+	proxy.FailureCount++
+	failuresTotal.WithLabelValues(proxy.Host+":"+proxy.Port, "healthcheck").Inc()
+	// REQ-GO-009: Log proxy usage and rotation events
+	log.Printf("[HEALTH] Proxy %s:%s UNHEALTHY (failures: %d, error: %v)",
+		proxy.Host, proxy.Port, proxy.FailureCount, err)
+
+	// REQ-GO-008: Remove non-functional proxies (mark as unhealthy after N failures)
+	if proxy.FailureCount >= getRetryLimit() {
+		proxy.IsHealthy = false
+		log.Printf("[HEALTH] Proxy %s:%s marked as UNHEALTHY after %d consecutive failures",
+			proxy.Host, proxy.Port, proxy.FailureCount)
+	}
+}
+
+func (p *ProxyPool) recordHealthCheckSuccess(proxy *ProxyInfo, elapsedMs int64) {
+	proxy.FailureCount = 0
+	proxy.IsHealthy = true
+	proxy.CooldownUntil = time.Time{}
+
+	log.Printf("[HEALTH] Proxy %s:%s HEALTHY (response: %dms)", proxy.Host, proxy.Port, elapsedMs)
+}
+
+// RecordPassiveFailure is called by the forwarding path when an outbound
+// request through proxy observes a 5xx response or a timeout. Once
+// FailureCount reaches the retry limit, the proxy is tripped into a
+// cooldown for getCooldownDuration() before it's eligible for selection
+// again; a passing health check ends the cooldown early.
+func (p *ProxyPool) RecordPassiveFailure(proxy *ProxyInfo, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	proxy.FailureCount++
+	failuresTotal.WithLabelValues(proxy.Host+":"+proxy.Port, "passive").Inc()
+	log.Printf("[HEALTH] Passive failure for proxy %s:%s (reason: %s, failures: %d)",
+		proxy.Host, proxy.Port, reason, proxy.FailureCount)
+
+	if proxy.FailureCount >= getRetryLimit() {
+		proxy.CooldownUntil = time.Now().Add(getCooldownDuration())
+		log.Printf("[HEALTH] Proxy %s:%s tripped into cooldown until %s",
+			proxy.Host, proxy.Port, proxy.CooldownUntil.Format(time.RFC3339))
+	}
+}
+
+// RecordPassiveSuccess resets a proxy's passive failure count after a
+// successful outbound request through it.
+func (p *ProxyPool) RecordPassiveSuccess(proxy *ProxyInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	proxy.FailureCount = 0
+	if !proxy.inCooldown() {
+		proxy.IsHealthy = true
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestStatusRangeDefaultsTo2xx(t *testing.T) {
+	cfg := ActiveHealthCheckConfig{}
+	min, max := cfg.statusRange()
+	if min != 200 || max != 299 {
+		t.Errorf("statusRange() = (%d, %d), want (200, 299) for an empty ExpectedStatus", min, max)
+	}
+}
+
+func TestStatusRangeParsesRange(t *testing.T) {
+	cfg := ActiveHealthCheckConfig{ExpectedStatus: "200-299"}
+	min, max := cfg.statusRange()
+	if min != 200 || max != 299 {
+		t.Errorf("statusRange() = (%d, %d), want (200, 299)", min, max)
+	}
+}
+
+func TestStatusRangeParsesSingleCode(t *testing.T) {
+	cfg := ActiveHealthCheckConfig{ExpectedStatus: "204"}
+	min, max := cfg.statusRange()
+	if min != 204 || max != 204 {
+		t.Errorf("statusRange() = (%d, %d), want (204, 204)", min, max)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus metrics for the proxy pool. Registered against the default
+// registry so they're exposed alongside the standard Go runtime collectors
+// on /metrics.
+// REQ-GO-015: Prometheus metrics
+var (
+	poolTotalGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "proxy_pool_total",
+		Help: "Total number of proxies configured in the pool.",
+	})
+
+	poolHealthyGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "proxy_pool_healthy",
+		Help: "Number of proxies currently considered healthy.",
+	})
+
+	selectionTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "proxy_selection_total",
+		Help: "Number of times a proxy was selected, by proxy and selection policy.",
+	}, []string{"proxy", "policy"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "proxy_request_duration_seconds",
+		Help:    "Duration of requests forwarded through each proxy.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"proxy"})
+
+	failuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "proxy_failures_total",
+		Help: "Number of observed proxy failures, by proxy and reason (healthcheck, passive).",
+	}, []string{"proxy", "reason"})
+
+	healthcheckDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "proxy_healthcheck_duration_seconds",
+		Help:    "Duration of proxy health check probes.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	rateLimitRejectionsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rate_limit_rejections_total",
+		Help: "Number of requests rejected by the rate limiter.",
+	})
+)
+
+// updatePoolGauges refreshes proxy_pool_total/proxy_pool_healthy from the
+// pool's current state.
+func (p *ProxyPool) updatePoolGauges() {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	healthy := 0
+	for _, proxy := range p.proxies {
+		if proxy.IsHealthy && !proxy.inCooldown() {
+			healthy++
+		}
+	}
+
+	poolTotalGauge.Set(float64(len(p.proxies)))
+	poolHealthyGauge.Set(float64(healthy))
+}
+
+// metricsHandler exposes the Prometheus text format on /metrics.
+func metricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// handleDashboard is the "/json" status dashboard: a machine-readable
+// snapshot of server version, uptime, configuration, and per-proxy stats,
+// in the spirit of a reverse proxy's admin dashboard API.
+// REQ-GO-016: Richer /json dashboard endpoint
+func (p *ProxyPool) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	p.mu.RLock()
+	proxies := make([]*ProxyInfo, len(p.proxies))
+	copy(proxies, p.proxies)
+	p.mu.RUnlock()
+
+	healthy := 0
+	for _, proxy := range proxies {
+		if proxy.IsHealthy && !proxy.inCooldown() {
+			healthy++
+		}
+	}
+
+	sendJSONResponse(w, map[string]interface{}{
+		"service":          "Proxy Management Service",
+		"version":          serviceVersion,
+		"uptime_seconds":   time.Since(p.startTime).Seconds(),
+		"port":             getPortFromEnv(),
+		"selection_policy": p.policy.Name(),
+		"rate_limit":       p.rateLimiter.describe(),
+		"proxies":          proxies,
+		"stats": map[string]interface{}{
+			"total_proxies":     len(proxies),
+			"healthy_proxies":   healthy,
+			"unhealthy_proxies": len(proxies) - healthy,
+		},
+	}, http.StatusOK)
+}
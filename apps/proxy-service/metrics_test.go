@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestUpdatePoolGaugesReflectsHealthyCount(t *testing.T) {
+	pool := &ProxyPool{
+		proxies: []*ProxyInfo{
+			{Host: "a", Port: "1", IsHealthy: true},
+			{Host: "b", Port: "2", IsHealthy: true},
+			{Host: "c", Port: "3", IsHealthy: false},
+		},
+	}
+
+	pool.updatePoolGauges()
+
+	if got := testutil.ToFloat64(poolTotalGauge); got != 3 {
+		t.Errorf("proxy_pool_total = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(poolHealthyGauge); got != 2 {
+		t.Errorf("proxy_pool_healthy = %v, want 2", got)
+	}
+}
@@ -4,28 +4,32 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
-	"sync/atomic"
+	"syscall"
 	"time"
 )
 
 // ProxyInfo represents detailed information about a proxy server
 // REQ-GO-002: Maintains a pool of proxy servers with metadata
 type ProxyInfo struct {
-	URL          string    `json:"url"`           // Full proxy URL (e.g., http://host:port)
-	Host         string    `json:"host"`          // Proxy host
-	Port         string    `json:"port"`          // Proxy port
-	Protocol     string    `json:"protocol"`      // http, https, socks5
-	IsHealthy    bool      `json:"is_healthy"`    // Current health status
-	LastChecked  time.Time `json:"last_checked"`  // Last health check timestamp
-	FailureCount int       `json:"failure_count"` // Consecutive failure count
-	SuccessCount int       `json:"success_count"` // Total successful uses
-	ResponseTime int64     `json:"response_time"` // Last response time in milliseconds
-	LastUsed     time.Time `json:"last_used"`     // Last time this proxy was used
+	URL           string                  `json:"url"`                      // Full proxy URL (e.g., http://host:port)
+	Host          string                  `json:"host"`                     // Proxy host
+	Port          string                  `json:"port"`                     // Proxy port
+	Protocol      string                  `json:"protocol"`                 // http, https, socks4, socks5
+	Weight        int                     `json:"weight"`                   // Relative weight for the weighted selection policy
+	IsHealthy     bool                    `json:"is_healthy"`               // Current health status
+	LastChecked   time.Time               `json:"last_checked"`             // Last health check timestamp
+	FailureCount  int                     `json:"failure_count"`            // Consecutive failure count
+	SuccessCount  int                     `json:"success_count"`            // Total successful uses
+	ResponseTime  int64                   `json:"response_time"`            // Last response time in milliseconds
+	LastUsed      time.Time               `json:"last_used"`                // Last time this proxy was used
+	Conns         int64                   `json:"conns"`                    // In-flight requests currently using this proxy (atomic)
+	CooldownUntil time.Time               `json:"cooldown_until,omitempty"` // Set when passive failures trip a cooldown
+	ActiveCheck   ActiveHealthCheckConfig `json:"-"`                        // Per-proxy active health-check config
 }
 
 // ProxyPool manages a pool of proxy servers with rotation and health checking
@@ -35,11 +39,18 @@ type ProxyInfo struct {
 type ProxyPool struct {
 	proxies      []*ProxyInfo
 	current      uint64
+	policy       SelectionPolicy
+	rateLimiter  *rateLimiter
 	mu           sync.RWMutex
 	healthTicker *time.Ticker
 	stopChan     chan struct{}
+	startTime    time.Time
 }
 
+// serviceVersion is reported by the "/" status endpoint and the "/json"
+// dashboard.
+const serviceVersion = "1.0.0"
+
 // ProxyResponse is the JSON response structure for API clients
 // REQ-GO-API-002: Returns JSON response with proxy details
 type ProxyResponse struct {
@@ -59,23 +70,37 @@ type ErrorResponse struct {
 
 // NewProxyPool creates and initializes a new proxy pool
 // REQ-GO-002: Maintains a pool of proxy servers
-func NewProxyPool(proxyURLs []string) *ProxyPool {
-	proxies := make([]*ProxyInfo, 0, len(proxyURLs))
+func NewProxyPool(entries []proxyEntry) *ProxyPool {
+	proxies := make([]*ProxyInfo, 0, len(entries))
+	defaultCheck := activeHealthCheckConfigFromEnv()
 
-	for _, proxyURL := range proxyURLs {
-		info := parseProxyURL(proxyURL)
+	for _, entry := range entries {
+		info := parseProxyURL(entry.URL)
 		if info != nil {
+			info.Weight = entry.Weight
+			if info.Weight <= 0 {
+				info.Weight = 1
+			}
+			if entry.HealthCheck != nil {
+				info.ActiveCheck = *entry.HealthCheck
+			} else {
+				info.ActiveCheck = defaultCheck
+			}
 			proxies = append(proxies, info)
-			log.Printf("[INIT] Added proxy: %s (protocol: %s)", info.Host, info.Protocol)
+			log.Printf("[INIT] Added proxy: %s (protocol: %s, weight: %d)", info.Host, info.Protocol, info.Weight)
 		}
 	}
 
 	pool := &ProxyPool{
-		proxies:  proxies,
-		current:  0,
-		stopChan: make(chan struct{}),
+		proxies:   proxies,
+		current:   0,
+		policy:    selectionPolicyFromEnv(),
+		stopChan:  make(chan struct{}),
+		startTime: time.Now(),
 	}
 
+	pool.updatePoolGauges()
+
 	// Start background health checking
 	pool.startHealthChecking()
 
@@ -126,43 +151,55 @@ func parseProxyURL(proxyURL string) *ProxyInfo {
 	return info
 }
 
-// GetNextProxy returns the next available healthy proxy using round-robin
+// GetNextProxy returns the next available healthy proxy using the pool's
+// configured SelectionPolicy (round-robin by default).
 // REQ-GO-003: Implements proxy rotation logic
 // REQ-GO-004: Validates proxy availability before rotation
 // REQ-GO-007: Handles concurrent proxy requests
-func (p *ProxyPool) GetNextProxy() (*ProxyInfo, error) {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
+func (p *ProxyPool) GetNextProxy(r *http.Request) (*ProxyInfo, error) {
+	return p.selectWith(p.policy, r)
+}
+
+// selectWith runs the given policy over the pool's healthy proxies. It is
+// split out from GetNextProxy so callers (e.g. the ?policy= override on
+// /proxy/next) can select with a one-off policy without mutating the pool's
+// default. It takes the pool's write lock (not just a read lock) because it
+// also updates the chosen proxy's LastUsed/SuccessCount, and those aren't
+// safe for concurrent mutation.
+func (p *ProxyPool) selectWith(policy SelectionPolicy, r *http.Request) (*ProxyInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
 	if len(p.proxies) == 0 {
 		return nil, fmt.Errorf("no proxies available in the pool")
 	}
 
-	// Try to find a healthy proxy (max attempts = pool size)
-	attempts := 0
-	maxAttempts := len(p.proxies)
-
-	for attempts < maxAttempts {
-		index := atomic.AddUint64(&p.current, 1) % uint64(len(p.proxies))
-		proxy := p.proxies[index]
-
+	healthy := make([]*ProxyInfo, 0, len(p.proxies))
+	for _, proxy := range p.proxies {
 		// REQ-GO-004: Validate proxy availability before rotation
-		if proxy.IsHealthy {
-			proxy.LastUsed = time.Now()
-			proxy.SuccessCount++
-
-			// REQ-GO-009: Log proxy usage and rotation events
-			log.Printf("[ROTATION] Selected proxy: %s:%s (health: %v, success: %d, failures: %d)",
-				proxy.Host, proxy.Port, proxy.IsHealthy, proxy.SuccessCount, proxy.FailureCount)
-
-			return proxy, nil
+		if proxy.IsHealthy && !proxy.inCooldown() {
+			healthy = append(healthy, proxy)
 		}
+	}
 
-		attempts++
+	if len(healthy) == 0 {
+		return nil, fmt.Errorf("no healthy proxies available (checked %d proxies)", len(p.proxies))
 	}
 
-	// All proxies are unhealthy
-	return nil, fmt.Errorf("no healthy proxies available (checked %d proxies)", maxAttempts)
+	proxy, err := policy.Select(healthy, r)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy.LastUsed = time.Now()
+	proxy.SuccessCount++
+	selectionTotal.WithLabelValues(proxy.Host+":"+proxy.Port, policy.Name()).Inc()
+
+	// REQ-GO-009: Log proxy usage and rotation events
+	log.Printf("[ROTATION] Selected proxy: %s:%s via %s (health: %v, success: %d, failures: %d)",
+		proxy.Host, proxy.Port, policy.Name(), proxy.IsHealthy, proxy.SuccessCount, proxy.FailureCount)
+
+	return proxy, nil
 }
 
 // getRetryLimit retrieves the retry limit from environment variable or defaults to 3
@@ -178,44 +215,6 @@ func getRetryLimit() int {
 	return retryLimit
 }
 
-// checkProxyHealth validates if a proxy is reachable and functional
-// REQ-GO-004: Validates proxy availability
-func (p *ProxyPool) checkProxyHealth(proxy *ProxyInfo) bool {
-	// Create a connection with timeout
-	timeout := 5 * time.Second
-	start := time.Now()
-
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%s", proxy.Host, proxy.Port), timeout)
-
-	elapsed := time.Since(start).Milliseconds()
-	proxy.ResponseTime = elapsed
-	proxy.LastChecked = time.Now()
-
-	if err != nil {
-		proxy.FailureCount++
-		// REQ-GO-009: Log proxy usage and rotation events
-		log.Printf("[HEALTH] Proxy %s:%s UNHEALTHY (failures: %d, error: %v)",
-			proxy.Host, proxy.Port, proxy.FailureCount, err)
-
-		// REQ-GO-008: Remove non-functional proxies (mark as unhealthy after 3 failures)
-		if proxy.FailureCount >= getRetryLimit() {
-			proxy.IsHealthy = false
-			log.Printf("[HEALTH] Proxy %s:%s marked as UNHEALTHY after %d consecutive failures",
-				proxy.Host, proxy.Port, proxy.FailureCount)
-		}
-		return false
-	}
-
-	conn.Close()
-
-	// Reset failure count on success
-	proxy.FailureCount = 0
-	proxy.IsHealthy = true
-
-	log.Printf("[HEALTH] Proxy %s:%s HEALTHY (response: %dms)", proxy.Host, proxy.Port, elapsed)
-	return true
-}
-
 func getHealthCheckInterval() time.Duration {
 	interval := 60 // Default interval in seconds
 
@@ -282,6 +281,9 @@ func (p *ProxyPool) runHealthCheck() {
 	}
 
 	log.Printf("[HEALTH] Health check completed: %d/%d proxies healthy", healthyCount, len(p.proxies))
+
+	poolTotalGauge.Set(float64(len(p.proxies)))
+	poolHealthyGauge.Set(float64(healthyCount))
 }
 
 // Stop gracefully stops the proxy pool
@@ -318,8 +320,25 @@ func (p *ProxyPool) handleNextProxy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// REQ-GO-007: Handles concurrent proxy requests
-	proxy, err := p.GetNextProxy()
+	var (
+		proxy *ProxyInfo
+		err   error
+	)
+
+	// A ?policy= override lets a caller pick a one-off selection policy
+	// without changing PROXY_SERVICE_SELECTION_POLICY for everyone else.
+	if override := r.URL.Query().Get("policy"); override != "" {
+		policy, perr := selectionPolicyByName(override)
+		if perr != nil {
+			sendErrorResponse(w, "Invalid policy", perr.Error(), http.StatusBadRequest)
+			return
+		}
+		proxy, err = p.selectWith(policy, r)
+	} else {
+		// REQ-GO-007: Handles concurrent proxy requests
+		proxy, err = p.GetNextProxy(r)
+	}
+
 	if err != nil {
 		sendErrorResponse(w, "No proxy available", err.Error(), http.StatusServiceUnavailable)
 		return
@@ -374,24 +393,55 @@ func sendErrorResponse(w http.ResponseWriter, error string, message string, stat
 	sendJSONResponse(w, response, statusCode)
 }
 
-// loadProxiesFromFile loads proxy URLs from JSON file
-func loadProxiesFromFile(filename string) []string {
+// proxyEntry is the shape of a single proxies.json element. Most entries are
+// plain URL strings, but an entry may instead be a JSON object to carry
+// per-proxy configuration such as a selection weight or an active
+// health-check probe.
+type proxyEntry struct {
+	URL         string                   `json:"url"`
+	Weight      int                      `json:"weight"`
+	HealthCheck *ActiveHealthCheckConfig `json:"health_check"`
+}
+
+// loadProxiesFromFile loads proxy entries from a JSON file. Each array
+// element may be either a plain URL string ("http://host:port") or an
+// object ({"url": "...", "weight": 5}).
+func loadProxiesFromFile(filename string) []proxyEntry {
 	file, err := os.Open(filename)
 	if err != nil {
 		log.Printf("[CONFIG] Could not open proxies file %s: %v", filename, err)
-		return []string{}
+		return []proxyEntry{}
 	}
 	defer file.Close()
 
-	var proxies []string
+	var rawEntries []json.RawMessage
 	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&proxies); err != nil {
+	if err := decoder.Decode(&rawEntries); err != nil {
 		log.Printf("[CONFIG] Could not parse proxies file %s: %v", filename, err)
-		return []string{}
+		return []proxyEntry{}
 	}
 
-	log.Printf("[CONFIG] Loaded %d proxies from %s", len(proxies), filename)
-	return proxies
+	entries := make([]proxyEntry, 0, len(rawEntries))
+	for _, raw := range rawEntries {
+		var url string
+		if err := json.Unmarshal(raw, &url); err == nil {
+			entries = append(entries, proxyEntry{URL: url, Weight: 1})
+			continue
+		}
+
+		var entry proxyEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			log.Printf("[CONFIG] Skipping unparseable proxies.json entry: %s", raw)
+			continue
+		}
+		if entry.Weight <= 0 {
+			entry.Weight = 1
+		}
+		entries = append(entries, entry)
+	}
+
+	log.Printf("[CONFIG] Loaded %d proxies from %s", len(entries), filename)
+	return entries
 }
 
 // getPortFromEnv gets the server port from environment variable or returns default
@@ -415,54 +465,6 @@ func getRateLimit() int {
 	return rateLimit
 }
 
-// rateLimitMiddleware implements basic rate limiting
-// REQ-GO-API-003: Implements rate limiting
-func rateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	var (
-		requests = make(map[string][]time.Time)
-		mu       sync.Mutex
-	)
-
-	limit := getRateLimit()
-	window := time.Minute
-
-	return func(w http.ResponseWriter, r *http.Request) {
-		mu.Lock()
-		defer mu.Unlock()
-
-		// Get client IP
-		ip := r.RemoteAddr
-		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-			ip = forwarded
-		}
-
-		// Clean old requests outside the time window
-		now := time.Now()
-		if times, exists := requests[ip]; exists {
-			var validRequests []time.Time
-			for _, t := range times {
-				if now.Sub(t) < window {
-					validRequests = append(validRequests, t)
-				}
-			}
-			requests[ip] = validRequests
-		}
-
-		// Check if limit exceeded
-		if len(requests[ip]) >= limit {
-			sendErrorResponse(w, "Rate limit exceeded",
-				fmt.Sprintf("Maximum %d requests per minute allowed", limit),
-				http.StatusTooManyRequests)
-			return
-		}
-
-		// Add current request
-		requests[ip] = append(requests[ip], now)
-
-		next(w, r)
-	}
-}
-
 // loggingMiddleware logs all HTTP requests
 func loggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -496,8 +498,8 @@ func main() {
 	// Fallback to default proxies if file is empty
 	if len(proxies) == 0 {
 		log.Println("[CONFIG] No proxies loaded from file, using default proxies")
-		proxies = []string{
-			"http://108.141.130.146:80",
+		proxies = []proxyEntry{
+			{URL: "http://108.141.130.146:80", Weight: 1},
 		}
 	}
 
@@ -505,33 +507,79 @@ func main() {
 	pool := NewProxyPool(proxies)
 	defer pool.Stop()
 
+	// REQ-GO-018: Token-bucket rate limiting and a global concurrency ceiling
+	limiter := newRateLimiter(loadRateLimitConfig())
+	pool.rateLimiter = limiter
+	maxConcurrent := getMaxConcurrent()
+	concurrency := newConcurrencyLimiter(maxConcurrent)
+
 	// Setup HTTP routes
 	http.HandleFunc("/", loggingMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		// A client configured to use this service as its HTTP proxy sends
+		// CONNECT requests straight to "/" (the request target is the
+		// authority, not a path), so route those into the tunnel handler.
+		if r.Method == http.MethodConnect {
+			pool.handleConnectTunnel(w, r)
+			return
+		}
+
 		stats := pool.GetProxyStats()
 		sendJSONResponse(w, map[string]interface{}{
 			"service": "Proxy Management Service",
-			"version": "1.0.0",
+			"version": serviceVersion,
 			"status":  "running",
 			"stats":   stats,
 			"endpoints": map[string]string{
-				"GET /proxy/next": "Get next available proxy",
-				"GET /proxies":    "List all proxies with status",
-				"GET /health":     "Service health check",
+				"GET /proxy/next":                   "Get next available proxy",
+				"GET/POST /proxy/forward":           "Forward a request through a pool proxy",
+				"GET /proxies":                      "List all proxies with status",
+				"GET /health":                       "Service health check",
+				"GET /metrics":                      "Prometheus metrics",
+				"GET /json":                         "Status dashboard",
+				"POST /admin/reload":                "Reload proxies.json (requires bearer token)",
+				"POST /admin/proxies":               "Add a proxy (requires bearer token)",
+				"DELETE /admin/proxies/{host:port}": "Remove a proxy (requires bearer token)",
 			},
 		}, http.StatusOK)
 	}))
 
 	// REQ-GO-API-001: Expose endpoint /proxy/next
-	http.HandleFunc("/proxy/next", loggingMiddleware(rateLimitMiddleware(pool.handleNextProxy)))
+	http.HandleFunc("/proxy/next", loggingMiddleware(concurrency.wrap(tokenBucketMiddleware(limiter, pool.handleNextProxy))))
+
+	// REQ-GO-014: Expose endpoint /proxy/forward
+	http.HandleFunc("/proxy/forward", loggingMiddleware(concurrency.wrap(tokenBucketMiddleware(limiter, pool.handleForward))))
 
 	// Additional endpoints
-	http.HandleFunc("/proxies", loggingMiddleware(pool.handleListProxies))
+	http.HandleFunc("/proxies", loggingMiddleware(concurrency.wrap(tokenBucketMiddleware(limiter, pool.handleListProxies))))
 	http.HandleFunc("/health", loggingMiddleware(pool.handleHealth))
 
+	// REQ-GO-015/REQ-GO-016: Metrics and dashboard endpoints
+	http.Handle("/metrics", metricsHandler())
+	http.HandleFunc("/json", loggingMiddleware(pool.handleDashboard))
+
+	// REQ-GO-017: Admin API for hot-reloading the proxy list
+	http.HandleFunc("/admin/reload", loggingMiddleware(pool.handleAdminReload))
+	http.HandleFunc("/admin/proxies", loggingMiddleware(pool.handleAdminProxies))
+	http.HandleFunc("/admin/proxies/", loggingMiddleware(pool.handleAdminProxyByKey))
+
+	// REQ-GO-017: SIGHUP re-reads proxies.json and hot-reloads the pool
+	reloadSignal := make(chan os.Signal, 1)
+	signal.Notify(reloadSignal, syscall.SIGHUP)
+	go func() {
+		for range reloadSignal {
+			log.Println("[RELOAD] Received SIGHUP, reloading proxies.json")
+			entries := loadProxiesFromFile("proxies.json")
+			if len(entries) == 0 {
+				log.Println("[RELOAD] proxies.json empty or unreadable, keeping current pool")
+				continue
+			}
+			pool.Reload(entries)
+		}
+	}()
+
 	// REQ-GO-API-004: Service runs on configurable port
 	port := getPortFromEnv()
 	serviceTimeout := getServiceTimeout()
-	rateLimit := getRateLimit()
 	addr := fmt.Sprintf(":%s", port)
 
 	log.Printf("[SERVER] Starting Proxy Management Service on port %s", port)
@@ -539,7 +587,11 @@ func main() {
 	log.Printf("[SERVER]   - GET /proxy/next  : Get next available proxy")
 	log.Printf("[SERVER]   - GET /proxies     : List all proxies")
 	log.Printf("[SERVER]   - GET /health      : Health check")
-	log.Printf("[SERVER] Rate limit: %d requests/minute per IP", rateLimit)
+	log.Printf("[SERVER] Rate limit strategy: %s", limiter.config.Strategy)
+	log.Printf("[SERVER] Selection policy: %s", pool.policy.Name())
+	if maxConcurrent > 0 {
+		log.Printf("[SERVER] Max concurrent requests: %d", maxConcurrent)
+	}
 
 	server := &http.Server{
 		Addr:         addr,
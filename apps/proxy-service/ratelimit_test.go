@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRateLimiterKeyForByStrategy(t *testing.T) {
+	cases := []struct {
+		name     string
+		strategy string
+		req      *http.Request
+		want     string
+	}{
+		{
+			name:     "api_key strategy uses X-API-Key header",
+			strategy: "api_key",
+			req:      &http.Request{Header: http.Header{"X-Api-Key": []string{"secret123"}}},
+			want:     "apikey:secret123",
+		},
+		{
+			name:     "api_key strategy falls back to ip when header missing",
+			strategy: "api_key",
+			req:      &http.Request{RemoteAddr: "192.0.2.1:1234"},
+			want:     "ip:192.0.2.1",
+		},
+		{
+			name:     "subject strategy uses X-Auth-Subject header",
+			strategy: "subject",
+			req:      &http.Request{Header: http.Header{"X-Auth-Subject": []string{"user-42"}}},
+			want:     "subject:user-42",
+		},
+		{
+			name:     "default strategy prefers X-Forwarded-For",
+			strategy: "ip",
+			req: &http.Request{
+				Header:     http.Header{"X-Forwarded-For": []string{"203.0.113.9, 10.0.0.1"}},
+				RemoteAddr: "192.0.2.1:1234",
+			},
+			want: "ip:203.0.113.9",
+		},
+		{
+			name:     "default strategy falls back to RemoteAddr",
+			strategy: "ip",
+			req:      &http.Request{RemoteAddr: "192.0.2.1:1234"},
+			want:     "ip:192.0.2.1",
+		},
+	}
+
+	for _, tc := range cases {
+		rl := &rateLimiter{config: RateLimitConfig{Strategy: tc.strategy}}
+		if got := rl.keyFor(tc.req); got != tc.want {
+			t.Errorf("%s: keyFor() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRateLimiterRouteLimit(t *testing.T) {
+	rl := &rateLimiter{
+		config: RateLimitConfig{
+			Default: RouteLimit{Rate: 1, Burst: 1},
+			Routes: map[string]RouteLimit{
+				"/proxy/forward": {Rate: 10, Burst: 20},
+			},
+		},
+	}
+
+	if got := rl.routeLimit("/proxy/forward"); got != (RouteLimit{Rate: 10, Burst: 20}) {
+		t.Errorf("routeLimit(%q) = %+v, want the configured override", "/proxy/forward", got)
+	}
+
+	if got := rl.routeLimit("/proxy/next"); got != (RouteLimit{Rate: 1, Burst: 1}) {
+		t.Errorf("routeLimit(%q) = %+v, want the default", "/proxy/next", got)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// SelectionPolicy picks one proxy out of a pre-filtered slice of healthy
+// proxies. Implementations must not mutate the slice; they may assume it is
+// non-empty, which the caller (ProxyPool.selectWith or selectExcluding)
+// guarantees.
+// REQ-GO-010: Pluggable upstream selection policies
+type SelectionPolicy interface {
+	// Name identifies the policy, e.g. for logging and the ?policy= override.
+	Name() string
+	// Select returns one proxy from proxies. req is the client request (it
+	// may be nil); only IPHash uses it, other policies ignore it.
+	Select(proxies []*ProxyInfo, req *http.Request) (*ProxyInfo, error)
+}
+
+// RoundRobinPolicy cycles through the pool in order. This is the historical
+// default behavior of GetNextProxy.
+type RoundRobinPolicy struct {
+	counter uint64
+}
+
+func NewRoundRobinPolicy() *RoundRobinPolicy {
+	return &RoundRobinPolicy{}
+}
+
+func (p *RoundRobinPolicy) Name() string { return "round_robin" }
+
+func (p *RoundRobinPolicy) Select(proxies []*ProxyInfo, req *http.Request) (*ProxyInfo, error) {
+	index := atomic.AddUint64(&p.counter, 1) % uint64(len(proxies))
+	return proxies[index], nil
+}
+
+// RandomPolicy picks a uniformly random healthy proxy.
+type RandomPolicy struct{}
+
+func NewRandomPolicy() *RandomPolicy { return &RandomPolicy{} }
+
+func (p *RandomPolicy) Name() string { return "random" }
+
+func (p *RandomPolicy) Select(proxies []*ProxyInfo, req *http.Request) (*ProxyInfo, error) {
+	return proxies[rand.Intn(len(proxies))], nil
+}
+
+// LeastConnectionsPolicy picks the proxy with the fewest in-flight requests,
+// as tracked by ProxyInfo.Conns. Callers of the forwarding path are
+// responsible for incrementing/decrementing Conns around the request.
+type LeastConnectionsPolicy struct{}
+
+func NewLeastConnectionsPolicy() *LeastConnectionsPolicy { return &LeastConnectionsPolicy{} }
+
+func (p *LeastConnectionsPolicy) Name() string { return "least_connections" }
+
+func (p *LeastConnectionsPolicy) Select(proxies []*ProxyInfo, req *http.Request) (*ProxyInfo, error) {
+	best := proxies[0]
+	bestConns := atomic.LoadInt64(&best.Conns)
+
+	for _, proxy := range proxies[1:] {
+		conns := atomic.LoadInt64(&proxy.Conns)
+		if conns < bestConns {
+			best = proxy
+			bestConns = conns
+		}
+	}
+
+	return best, nil
+}
+
+// LeastResponseTimePolicy picks the healthy proxy with the lowest last
+// observed ResponseTime.
+type LeastResponseTimePolicy struct{}
+
+func NewLeastResponseTimePolicy() *LeastResponseTimePolicy { return &LeastResponseTimePolicy{} }
+
+func (p *LeastResponseTimePolicy) Name() string { return "least_response_time" }
+
+func (p *LeastResponseTimePolicy) Select(proxies []*ProxyInfo, req *http.Request) (*ProxyInfo, error) {
+	best := proxies[0]
+
+	for _, proxy := range proxies[1:] {
+		if proxy.ResponseTime < best.ResponseTime {
+			best = proxy
+		}
+	}
+
+	return best, nil
+}
+
+// WeightedPolicy picks a proxy at random, biased by its configured Weight.
+// A proxy with Weight <= 0 is treated as Weight 1 so it can still be chosen.
+type WeightedPolicy struct{}
+
+func NewWeightedPolicy() *WeightedPolicy { return &WeightedPolicy{} }
+
+func (p *WeightedPolicy) Name() string { return "weighted" }
+
+func (p *WeightedPolicy) Select(proxies []*ProxyInfo, req *http.Request) (*ProxyInfo, error) {
+	total := 0
+	for _, proxy := range proxies {
+		weight := proxy.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		total += weight
+	}
+
+	target := rand.Intn(total)
+	for _, proxy := range proxies {
+		weight := proxy.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		if target < weight {
+			return proxy, nil
+		}
+		target -= weight
+	}
+
+	// Unreachable given the accumulation above, but keeps the compiler happy.
+	return proxies[len(proxies)-1], nil
+}
+
+// IPHashPolicy consistently hashes the client's address so the same client
+// keeps landing on the same upstream proxy, so long as the pool membership
+// doesn't change.
+type IPHashPolicy struct{}
+
+func NewIPHashPolicy() *IPHashPolicy { return &IPHashPolicy{} }
+
+func (p *IPHashPolicy) Name() string { return "ip_hash" }
+
+func (p *IPHashPolicy) Select(proxies []*ProxyInfo, req *http.Request) (*ProxyInfo, error) {
+	key := clientKey(req)
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	index := h.Sum32() % uint32(len(proxies))
+
+	return proxies[index], nil
+}
+
+// clientKey extracts the value IPHashPolicy hashes on: the first
+// X-Forwarded-For entry if present, otherwise the request's RemoteAddr.
+func clientKey(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+
+	if forwarded := req.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+
+	return req.RemoteAddr
+}
+
+// selectionPolicyByName resolves a policy name (as used by
+// PROXY_SERVICE_SELECTION_POLICY and ?policy=) to a SelectionPolicy.
+func selectionPolicyByName(name string) (SelectionPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "round_robin", "round-robin":
+		return NewRoundRobinPolicy(), nil
+	case "random":
+		return NewRandomPolicy(), nil
+	case "least_connections", "least-connections":
+		return NewLeastConnectionsPolicy(), nil
+	case "least_response_time", "least-response-time":
+		return NewLeastResponseTimePolicy(), nil
+	case "weighted":
+		return NewWeightedPolicy(), nil
+	case "ip_hash", "ip-hash":
+		return NewIPHashPolicy(), nil
+	default:
+		return nil, fmt.Errorf("unknown selection policy %q", name)
+	}
+}
+
+// selectionPolicyFromEnv builds the default pool policy from
+// PROXY_SERVICE_SELECTION_POLICY, falling back to round-robin.
+func selectionPolicyFromEnv() SelectionPolicy {
+	policy, err := selectionPolicyByName(os.Getenv("PROXY_SERVICE_SELECTION_POLICY"))
+	if err != nil {
+		return NewRoundRobinPolicy()
+	}
+	return policy
+}
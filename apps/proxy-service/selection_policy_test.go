@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func proxiesForTest() []*ProxyInfo {
+	return []*ProxyInfo{
+		{Host: "a", Port: "1", Weight: 1, ResponseTime: 50, Conns: 3},
+		{Host: "b", Port: "2", Weight: 1, ResponseTime: 10, Conns: 1},
+		{Host: "c", Port: "3", Weight: 1, ResponseTime: 30, Conns: 2},
+	}
+}
+
+func TestRoundRobinPolicyCycles(t *testing.T) {
+	policy := NewRoundRobinPolicy()
+	proxies := proxiesForTest()
+
+	seen := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		proxy, err := policy.Select(proxies, nil)
+		if err != nil {
+			t.Fatalf("Select: %v", err)
+		}
+		seen[proxy.Host]++
+	}
+
+	for _, proxy := range proxies {
+		if seen[proxy.Host] != 2 {
+			t.Errorf("proxy %s selected %d times, want 2 over 6 rounds", proxy.Host, seen[proxy.Host])
+		}
+	}
+}
+
+func TestLeastConnectionsPolicyPicksFewestConns(t *testing.T) {
+	policy := NewLeastConnectionsPolicy()
+	proxy, err := policy.Select(proxiesForTest(), nil)
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if proxy.Host != "b" {
+		t.Errorf("got %s, want b (fewest Conns)", proxy.Host)
+	}
+}
+
+func TestLeastResponseTimePolicyPicksFastest(t *testing.T) {
+	policy := NewLeastResponseTimePolicy()
+	proxy, err := policy.Select(proxiesForTest(), nil)
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if proxy.Host != "b" {
+		t.Errorf("got %s, want b (lowest ResponseTime)", proxy.Host)
+	}
+}
+
+func TestWeightedPolicyFavorsHigherWeight(t *testing.T) {
+	policy := NewWeightedPolicy()
+	proxies := []*ProxyInfo{
+		{Host: "light", Port: "1", Weight: 0}, // clamped to weight 1
+		{Host: "heavy", Port: "2", Weight: 99},
+	}
+
+	heavyWins := 0
+	const trials = 2000
+	for i := 0; i < trials; i++ {
+		proxy, err := policy.Select(proxies, nil)
+		if err != nil {
+			t.Fatalf("Select: %v", err)
+		}
+		if proxy.Host == "heavy" {
+			heavyWins++
+		}
+	}
+
+	if heavyWins < trials*9/10 {
+		t.Errorf("heavy (weight 99) won %d/%d, want at least 90%% given light is clamped to weight 1", heavyWins, trials)
+	}
+}
+
+func TestIPHashPolicyIsStickyPerClient(t *testing.T) {
+	policy := NewIPHashPolicy()
+	proxies := proxiesForTest()
+
+	req1 := &http.Request{Header: http.Header{"X-Forwarded-For": []string{"203.0.113.5"}}}
+	req2 := &http.Request{Header: http.Header{"X-Forwarded-For": []string{"203.0.113.5"}}}
+
+	first, err := policy.Select(proxies, req1)
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	second, err := policy.Select(proxies, req2)
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if first.Host != second.Host {
+		t.Errorf("same client key hashed to different proxies: %s vs %s", first.Host, second.Host)
+	}
+}
+
+func TestClientKeyPrefersForwardedFor(t *testing.T) {
+	req := &http.Request{
+		Header:     http.Header{"X-Forwarded-For": []string{"198.51.100.1, 10.0.0.1"}},
+		RemoteAddr: "192.0.2.1:54321",
+	}
+	if got := clientKey(req); got != "198.51.100.1" {
+		t.Errorf("clientKey() = %q, want first X-Forwarded-For entry", got)
+	}
+}
+
+func TestClientKeyFallsBackToRemoteAddr(t *testing.T) {
+	req := &http.Request{RemoteAddr: "192.0.2.1:54321"}
+	if got := clientKey(req); got != "192.0.2.1:54321" {
+		t.Errorf("clientKey() = %q, want RemoteAddr", got)
+	}
+}
+
+func TestSelectionPolicyByName(t *testing.T) {
+	cases := map[string]string{
+		"":                    "round_robin",
+		"round_robin":         "round_robin",
+		"round-robin":         "round_robin",
+		"random":              "random",
+		"least_connections":   "least_connections",
+		"least-response-time": "least_response_time",
+		"weighted":            "weighted",
+		"ip_hash":             "ip_hash",
+		" Ip-Hash ":           "ip_hash",
+	}
+
+	for name, want := range cases {
+		policy, err := selectionPolicyByName(name)
+		if err != nil {
+			t.Fatalf("selectionPolicyByName(%q): %v", name, err)
+		}
+		if policy.Name() != want {
+			t.Errorf("selectionPolicyByName(%q).Name() = %q, want %q", name, policy.Name(), want)
+		}
+	}
+}
+
+func TestSelectionPolicyByNameRejectsUnknown(t *testing.T) {
+	if _, err := selectionPolicyByName("not-a-policy"); err == nil {
+		t.Error("expected an error for an unknown policy name")
+	}
+}